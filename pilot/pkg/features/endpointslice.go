@@ -0,0 +1,49 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package features
+
+import "istio.io/istio/pkg/env"
+
+var EnableMCSServiceDiscovery = env.Register(
+	"ENABLE_MCS_SERVICE_DISCOVERY",
+	false,
+	"Watch EndpointSlices exported from other clusters via the sigs.k8s.io/mcs-api "+
+		"ServiceExport/ServiceImport flow and make them discoverable under the imported "+
+		"service's clusterset.local hostname.",
+).Get()
+
+var PreferTopologyAwareRoutingHints = env.Register(
+	"PREFER_TOPOLOGY_AWARE_ROUTING_HINTS",
+	false,
+	"Record EndpointSlice topology-aware hints (service.kubernetes.io/topology-mode: Auto) "+
+		"as endpoint labels, and have the endpoint cache's per-proxy lookup return only the "+
+		"endpoints hinted for the proxy's zone or node, or all endpoints when none match. "+
+		"EDS pushes still carry the unfiltered endpoint list.",
+).Get()
+
+var EnableGracefulTermination = env.Register(
+	"ENABLE_GRACEFUL_TERMINATION",
+	false,
+	"Report endpoints in the Serving=true, Ready=false, Terminating=true state as Draining "+
+		"for any service, not just ones using persistent sessions, so Envoy can keep sending "+
+		"traffic to endpoints that are shutting down but still handling requests.",
+).Get()
+
+var EnableFQDNEndpointSlices = env.Register(
+	"ENABLE_FQDN_ENDPOINTSLICES",
+	false,
+	"Populate the endpoint cache from AddressTypeFQDN EndpointSlices. Opt-in until Istio's "+
+		"cluster-building layer resolves the affected Services as LOGICAL_DNS/STRICT_DNS.",
+).Get()
@@ -15,7 +15,12 @@
 package controller
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	corev1 "k8s.io/api/core/v1"
@@ -25,29 +30,55 @@ import (
 	klabels "k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
 	mcs "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
 
 	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/cluster"
 	"istio.io/istio/pkg/config"
 	"istio.io/istio/pkg/config/host"
 	"istio.io/istio/pkg/config/schema/kind"
 	"istio.io/istio/pkg/config/visibility"
 	"istio.io/istio/pkg/kube/kclient"
+	"istio.io/istio/pkg/monitoring"
 	"istio.io/istio/pkg/util/sets"
 )
 
+// endpointSliceReconcileInterval bounds how stale endpointCache can get between informer-driven
+// updates: a slice deleted while pilot-discovery was down leaves no event to react to, so we
+// periodically re-derive the cache from the live set of slices.
+const endpointSliceReconcileInterval = 5 * time.Minute
+
+var reconciledStaleEndpointSlices = monitoring.NewSum(
+	"pilot_endpointslice_reconciled_stale_total",
+	"Number of stale endpointSliceCache entries pruned by the periodic reconciler.",
+)
+
 type endpointSliceController struct {
 	endpointCache *endpointSliceCache
 	slices        kclient.Client[*v1.EndpointSlice]
-	c             *Controller
+	// mcsSlices watches the same EndpointSlice resources, but selects only those
+	// exported from other clusters via the sigs.k8s.io/mcs-api ServiceExport/ServiceImport
+	// flow. It is only populated when features.EnableMCSServiceDiscovery is set.
+	mcsSlices kclient.Client[*v1.EndpointSlice]
+	c         *Controller
 }
 
 var (
 	endpointSliceRequirement = labelRequirement(mcs.LabelServiceName, selection.DoesNotExist, nil)
 	endpointSliceSelector    = klabels.NewSelector().Add(*endpointSliceRequirement)
+
+	// mcsEndpointSliceRequirement matches EndpointSlices generated by the mcs-api
+	// implementation for an imported Service, i.e. the complement of endpointSliceRequirement.
+	mcsEndpointSliceRequirement = labelRequirement(mcs.LabelServiceName, selection.Exists, nil)
+	mcsEndpointSliceSelector    = klabels.NewSelector().Add(*mcsEndpointSliceRequirement)
 )
 
+// mcsSourceClusterLabel is populated by mcs-api controllers on EndpointSlices that were
+// imported from a remote cluster, identifying which cluster the backing endpoints live in.
+const mcsSourceClusterLabel = "multicluster.kubernetes.io/source-cluster"
+
 func newEndpointSliceController(c *Controller) *endpointSliceController {
 	slices := kclient.NewFiltered[*v1.EndpointSlice](c.client, kclient.Filter{ObjectFilter: c.opts.GetFilter()})
 	out := &endpointSliceController{
@@ -56,9 +87,159 @@ func newEndpointSliceController(c *Controller) *endpointSliceController {
 		endpointCache: newEndpointSliceCache(),
 	}
 	registerHandlers[*v1.EndpointSlice](c, slices, "EndpointSlice", out.onEvent, nil)
+	if features.EnableMCSServiceDiscovery {
+		// A separate informer+handler keeps the MCS path fully opt-in and avoids
+		// interleaving its bookkeeping (shard key per source cluster, clusterset.local
+		// hostnames) with the plain single-cluster path above.
+		mcsSlices := kclient.NewFiltered[*v1.EndpointSlice](c.client, kclient.Filter{ObjectFilter: c.opts.GetFilter()})
+		out.mcsSlices = mcsSlices
+		registerHandlers[*v1.EndpointSlice](c, mcsSlices, "MCSEndpointSlice", out.onMCSEvent, nil)
+	}
 	return out
 }
 
+// Run waits for the EndpointSlice informers' initial sync (the MCS one too, when enabled), then reconciles endpointCache
+// against the live set of slices, and repeats the reconciliation periodically. This catches
+// entries left behind by slices that were deleted while pilot-discovery was not running:
+// initializeNamespace only issues Adds for what it finds on restart, so a slice deleted in the
+// interim never generates the Delete event endpointCache normally relies on.
+//
+// newEndpointSliceController does not start this goroutine itself - the owning Controller's own
+// Run (controller.go, not part of this trimmed tree) must call esc.Run(stopCh) alongside it, or
+// this periodic reconciliation, and the MCS stale-slice pruning it drives, never execute.
+func (esc *endpointSliceController) Run(stopCh <-chan struct{}) {
+	synced := []cache.InformerSynced{esc.slices.HasSynced}
+	if esc.mcsSlices != nil {
+		synced = append(synced, esc.mcsSlices.HasSynced)
+	}
+	if !cache.WaitForCacheSync(stopCh, synced...) {
+		return
+	}
+	esc.reconcile()
+	go func() {
+		ticker := time.NewTicker(endpointSliceReconcileInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+				esc.reconcile()
+			}
+		}
+	}()
+}
+
+// mcsReconcilePush identifies one (hostname, sourceCluster) shard that needs a pushMCSEDS call
+// after reconcile prunes a stale MCS slice, so a pruned remote cluster's withdrawal is pushed
+// under its own shard rather than bleeding into another cluster's.
+type mcsReconcilePush struct {
+	hostname      host.Name
+	namespace     string
+	sourceCluster cluster.ID
+}
+
+// reconcile prunes endpointCache entries for slices that no longer exist and pushes a single
+// EDS update per affected service (for an MCS-imported service, one per affected source-cluster
+// shard). MCS-imported (*.svc.clusterset.local) hostnames are handled
+// separately from plain hostnames: esc.c.GetService never resolves a clusterset.local hostname
+// (there is no local Service object for an import), and their live slices come from
+// esc.mcsSlices/mcsEndpointSliceSelector rather than esc.slices/endpointSliceSelectorForService.
+// Without this split, MCS hostnames would either be skipped forever (GetService returns nil) or
+// have every endpoint pruned as stale on the first pass (the plain-path selector can never match
+// an MCS slice's labels).
+func (esc *endpointSliceController) reconcile() {
+	var toPush []host.Name
+	mcsPush := sets.New[mcsReconcilePush]()
+	var stale int
+
+	esc.endpointCache.mu.Lock()
+	for hostname, bySlice := range esc.endpointCache.endpointsByServiceAndSlice {
+		namespace, isMCS, live, ok := esc.liveSliceKeysForHostname(hostname)
+		if !ok {
+			// The service is gone entirely; deleteEndpoints already handles that path.
+			continue
+		}
+		var prunedHost bool
+		for _, sliceKey := range staleSliceKeys(bySlice, live) {
+			esc.endpointCache.delete(hostname, sliceKey)
+			stale++
+			prunedHost = true
+			if isMCS {
+				mcsPush.Insert(mcsReconcilePush{hostname, namespace, mcsSliceKeySourceCluster(sliceKey)})
+			}
+		}
+		if prunedHost && !isMCS {
+			toPush = append(toPush, hostname)
+		}
+	}
+	esc.endpointCache.mu.Unlock()
+
+	if stale == 0 {
+		return
+	}
+	log.Infof("endpointslice reconcile: pruned %d stale cache entries across %d services", stale, len(toPush)+len(mcsPush))
+	reconciledStaleEndpointSlices.RecordInt(int64(stale))
+	for _, hostname := range toPush {
+		namespace := ""
+		if svc := esc.c.GetService(hostname); svc != nil {
+			namespace = svc.Attributes.Namespace
+		}
+		esc.pushEDS([]host.Name{hostname}, namespace)
+	}
+	for p := range mcsPush {
+		esc.pushMCSEDS(p.hostname, p.namespace, p.sourceCluster)
+	}
+}
+
+// liveSliceKeysForHostname reports the endpointCache slice keys that currently have a live
+// backing EndpointSlice for hostname, the namespace to push under, and whether hostname is
+// MCS-imported. ok is false if hostname no longer resolves to anything pilot-discovery knows
+// about, in which case its cache entries are left for deleteEndpoints/onEvent to handle instead.
+func (esc *endpointSliceController) liveSliceKeysForHostname(hostname host.Name) (namespace string, isMCS bool, live sets.Set[string], ok bool) {
+	if name, ns, isClusterSet := mcsClusterSetHostnameParts(hostname); isClusterSet {
+		if esc.mcsSlices == nil {
+			return "", true, nil, false
+		}
+		live = sets.New[string]()
+		for _, s := range esc.mcsSlices.List(ns, mcsEndpointSliceSelectorForService(name)) {
+			sourceCluster := cluster.ID(s.GetLabels()[mcsSourceClusterLabel])
+			live.Insert(mcsSliceKey(sourceCluster, s.Name))
+		}
+		return ns, true, live, true
+	}
+	svc := esc.c.GetService(hostname)
+	if svc == nil {
+		return "", false, nil, false
+	}
+	live = sets.New[string]()
+	for _, s := range esc.slices.List(svc.Attributes.Namespace, endpointSliceSelectorForService(svc.Attributes.Name)) {
+		live.Insert(s.Name)
+	}
+	return svc.Attributes.Namespace, false, live, true
+}
+
+// staleSliceKeys returns the slice keys present in cached but absent from live, i.e. the
+// entries reconcile should prune because their backing EndpointSlice no longer exists.
+func staleSliceKeys(cached map[string][]*model.IstioEndpoint, live sets.Set[string]) []string {
+	var stale []string
+	for sliceKey := range cached {
+		if !live.Contains(sliceKey) {
+			stale = append(stale, sliceKey)
+		}
+	}
+	return stale
+}
+
+// mcsSliceKeySourceCluster extracts the source cluster from a slice key produced by
+// mcsSliceKey, so reconcile can push a pruned MCS slice's withdrawal under its own shard.
+func mcsSliceKeySourceCluster(sliceKey string) cluster.ID {
+	if idx := strings.Index(sliceKey, "/"); idx >= 0 {
+		return cluster.ID(sliceKey[:idx])
+	}
+	return cluster.ID(sliceKey)
+}
+
 func (esc *endpointSliceController) podArrived(name, ns string) error {
 	ep := esc.slices.Get(name, ns)
 	if ep == nil {
@@ -173,11 +354,126 @@ func serviceNameForEndpointSlice(labels map[string]string) string {
 	return labels[v1beta1.LabelServiceName]
 }
 
+// onMCSEvent handles EndpointSlices exported from other clusters through the mcs-api
+// ServiceExport/ServiceImport flow. These are translated into IstioEndpoints attributed to
+// the clusterset.local hostname of the imported service, rather than the local cluster.local
+// hostname the plain endpointSliceController path uses.
+func (esc *endpointSliceController) onMCSEvent(_, ep *v1.EndpointSlice, event model.Event) error {
+	esLabels := ep.GetLabels()
+	if !mcsEndpointSliceSelector.Matches(klabels.Set(esLabels)) {
+		return nil
+	}
+	importedName := serviceNameForEndpointSlice(esLabels)
+	if importedName == "" {
+		return nil
+	}
+	hostName := mcsClusterSetHostname(importedName, ep.GetNamespace())
+	sourceCluster := cluster.ID(esLabels[mcsSourceClusterLabel])
+	sliceKey := mcsSliceKey(sourceCluster, ep.Name)
+
+	if event == model.EventDelete {
+		esc.endpointCache.Delete(hostName, sliceKey)
+	} else {
+		esc.updateMCSEndpointCacheForSlice(hostName, ep, sourceCluster, sliceKey)
+	}
+	esc.pushMCSEDS(hostName, ep.GetNamespace(), sourceCluster)
+	return nil
+}
+
+// mcsClusterSetHostname is the clusterset.local hostname Istio assigns to a ServiceImport,
+// mirroring the naming convention defined by the mcs-api spec.
+func mcsClusterSetHostname(name, namespace string) host.Name {
+	return host.Name(fmt.Sprintf("%s.%s.svc.clusterset.local", name, namespace))
+}
+
+// mcsSliceKey namespaces the endpointSliceCache slice key by source cluster so that identically
+// named slices in two different clusters exporting the same service never collide.
+func mcsSliceKey(sourceCluster cluster.ID, sliceName string) string {
+	return string(sourceCluster) + "/" + sliceName
+}
+
+// mcsClusterSetHostnameParts is the inverse of mcsClusterSetHostname: it recovers the imported
+// Service's name and namespace from a clusterset.local hostname, or reports ok=false if hostname
+// does not follow that naming convention (i.e. it is not MCS-imported).
+func mcsClusterSetHostnameParts(hostname host.Name) (name, namespace string, ok bool) {
+	const suffix = ".svc.clusterset.local"
+	s := string(hostname)
+	if !strings.HasSuffix(s, suffix) {
+		return "", "", false
+	}
+	parts := strings.Split(strings.TrimSuffix(s, suffix), ".")
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// mcsEndpointSliceSelectorForService is mcsEndpointSliceSelector narrowed to the slices exported
+// for a single imported service, mirroring endpointSliceSelectorForService's role for the plain
+// (non-MCS) path.
+func mcsEndpointSliceSelectorForService(name string) klabels.Selector {
+	return klabels.Set(map[string]string{
+		v1beta1.LabelServiceName: name,
+	}).AsSelectorPreValidated().Add(*mcsEndpointSliceRequirement)
+}
+
+func (esc *endpointSliceController) updateMCSEndpointCacheForSlice(hostName host.Name, slice *v1.EndpointSlice, sourceCluster cluster.ID, sliceKey string) {
+	if slice.AddressType == v1.AddressTypeFQDN {
+		// TODO(https://github.com/istio/istio/issues/34995) support FQDN endpointslice
+		return
+	}
+	svc := esc.c.GetService(hostName)
+	discoverabilityPolicy := esc.c.exports.EndpointDiscoverabilityPolicy(svc)
+	rawSvc := esc.parentServiceFor(slice)
+	publishNotReady := rawSvc != nil && rawSvc.Spec.PublishNotReadyAddresses
+
+	var endpoints []*model.IstioEndpoint
+	for _, e := range slice.Endpoints {
+		healthStatus := endpointHealthStatus(svc, e, publishNotReady)
+		for _, a := range e.Addresses {
+			// Imported endpoints have no local Pod object to enrich metadata from; the
+			// exporting cluster is responsible for attributing labels/service account.
+			builder := NewEndpointBuilder(esc.c, nil)
+			for _, port := range slice.Ports {
+				var portNum int32
+				if port.Port != nil {
+					portNum = *port.Port
+				}
+				var portName string
+				if port.Name != nil {
+					portName = *port.Name
+				}
+				istioEndpoint := builder.buildIstioEndpoint(a, portNum, portName, discoverabilityPolicy, healthStatus)
+				mirrorServiceLabels(istioEndpoint, slice, rawSvc)
+				endpoints = append(endpoints, istioEndpoint)
+			}
+		}
+	}
+	esc.endpointCache.Update(hostName, sliceKey, endpoints)
+}
+
+// pushMCSEDS pushes EDS updates for an imported service under a shard keyed by the source
+// cluster, so that endpoints from different remote clusters never overwrite one another and
+// can be withdrawn independently when a given cluster's export disappears. It must only push
+// sourceCluster's own slices - get() would flatten every cluster exporting this hostname into
+// one list, which would make each cluster's shard re-push every other cluster's endpoints too.
+func (esc *endpointSliceController) pushMCSEDS(hostname host.Name, namespace string, sourceCluster cluster.ID) {
+	shard := model.ShardKey{Cluster: sourceCluster}
+	esc.endpointCache.mu.RLock()
+	endpoints := esc.endpointCache.getForSourceCluster(hostname, sourceCluster)
+	esc.endpointCache.mu.RUnlock()
+	esc.c.opts.XDSUpdater.EDSUpdate(shard, string(hostname), namespace, endpoints)
+}
+
 func (esc *endpointSliceController) serviceTargets(ep *v1.EndpointSlice, proxy *model.Proxy) []model.ServiceTarget {
 	var out []model.ServiceTarget
 	esc.endpointCache.mu.RLock()
 	defer esc.endpointCache.mu.RUnlock()
 	for _, svc := range esc.c.servicesForNamespacedName(getServiceNamespacedName(ep)) {
+		// serviceTargets answers "what services is this proxy itself a member of" - topology
+		// hints narrow what a *caller* should be sent for an upstream, and must never be
+		// applied here, or a proxy could lose its own self-identification whenever its zone
+		// isn't the one Kubernetes hinted for its own endpoint.
 		for _, instance := range esc.endpointCache.get(svc.Hostname) {
 			port, f := svc.Ports.Get(instance.ServicePortName)
 			if !f {
@@ -225,39 +521,162 @@ func (esc *endpointSliceController) updateEndpointSlice(slice *v1.EndpointSlice)
 	}
 }
 
-func endpointHealthStatus(svc *model.Service, e v1.Endpoint) model.HealthStatus {
+func endpointHealthStatus(svc *model.Service, e v1.Endpoint, publishNotReadyAddresses bool) model.HealthStatus {
+	// Services with spec.publishNotReadyAddresses=true always consider their endpoints
+	// Healthy, regardless of the Ready condition, matching upstream conformance expectations.
+	if publishNotReadyAddresses {
+		return model.Healthy
+	}
+
 	if e.Conditions.Ready == nil || *e.Conditions.Ready {
 		return model.Healthy
 	}
 
+	draining := (e.Conditions.Serving == nil || *e.Conditions.Serving) &&
+		(e.Conditions.Terminating == nil || *e.Conditions.Terminating)
+	if !draining {
+		return model.UnHealthy
+	}
+
+	// EnableGracefulTermination extends draining-tracking, originally gated on persistent
+	// sessions, to any service so Envoy can keep sending traffic to endpoints that are
+	// shutting down but still handling requests.
+	if features.EnableGracefulTermination {
+		return model.Draining
+	}
 	if features.PersistentSessionLabel != "" &&
 		svc != nil &&
-		svc.Attributes.Labels[features.PersistentSessionLabel] != "" &&
-		(e.Conditions.Serving == nil || *e.Conditions.Serving) &&
-		(e.Conditions.Terminating == nil || *e.Conditions.Terminating) {
+		svc.Attributes.Labels[features.PersistentSessionLabel] != "" {
 		return model.Draining
 	}
 
 	return model.UnHealthy
 }
 
+// parentServiceFor returns the raw Kubernetes Service backing slice, for reading fields (like
+// spec.publishNotReadyAddresses, or the Service's own labels) that aren't carried on model.Service.
+func (esc *endpointSliceController) parentServiceFor(slice *v1.EndpointSlice) *corev1.Service {
+	return esc.c.services.Get(serviceNameForEndpointSlice(slice.GetLabels()), slice.Namespace)
+}
+
+const (
+	// endpointWeightsAnnotation lets a Service owner assign a per-endpoint LbWeight without a
+	// DestinationRule subset per version, e.g. for canary/blue-green weighting:
+	//   networking.istio.io/endpoint-weights: "<pod-name>=10,<pod-name>=1"
+	endpointWeightsAnnotation = "networking.istio.io/endpoint-weights"
+	// endpointWeightLabel is a per-pod fallback for the same purpose, consulted when the
+	// Service-level annotation above doesn't mention a given pod.
+	endpointWeightLabel = "networking.istio.io/endpoint-weight"
+)
+
+// endpointWeightsFrom parses endpointWeightsAnnotation into a pod-name -> weight map.
+// Malformed entries are logged and skipped rather than failing the whole annotation.
+func endpointWeightsFrom(rawSvc *corev1.Service) map[string]uint32 {
+	if rawSvc == nil || rawSvc.Annotations[endpointWeightsAnnotation] == "" {
+		return nil
+	}
+	weights := make(map[string]uint32)
+	for _, entry := range strings.Split(rawSvc.Annotations[endpointWeightsAnnotation], ",") {
+		name, weight, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		w, err := strconv.ParseUint(strings.TrimSpace(weight), 10, 32)
+		if !ok || name == "" || err != nil {
+			log.Warnf("invalid entry %q in %s annotation on service %s/%s, skipping",
+				entry, endpointWeightsAnnotation, rawSvc.Namespace, rawSvc.Name)
+			continue
+		}
+		weights[name] = uint32(w)
+	}
+	return weights
+}
+
+// endpointWeightFor resolves pod's LbWeight, preferring the Service-level annotation and
+// falling back to the pod's own endpointWeightLabel. The bool return reports whether a weight
+// was configured at all, so an explicit weight of 0 can be told apart from "unset".
+func endpointWeightFor(pod *corev1.Pod, weights map[string]uint32) (uint32, bool) {
+	if pod == nil {
+		return 0, false
+	}
+	if w, f := weights[pod.Name]; f {
+		return w, true
+	}
+	if v, f := pod.Labels[endpointWeightLabel]; f {
+		if w, err := strconv.ParseUint(v, 10, 32); err == nil {
+			return uint32(w), true
+		}
+	}
+	return 0, false
+}
+
+// isHeadlessServiceLabel is mirrored by Kubernetes' EndpointSlice controller onto the slice
+// (never the Service itself) to record whether the owning Service is headless.
+const isHeadlessServiceLabel = "service.kubernetes.io/headless"
+
+// mirrorServiceLabels attaches the parent Service's own labels, plus the well-known
+// IsHeadlessService/LabelServiceName/LabelManagedBy labels Kubernetes' EndpointSlice controller
+// mirrors onto the *slice* (not the Service), to an endpoint's metadata. This lets EnvoyFilter
+// authors and DestinationRule subsets key off service-owned labels - including
+// headless-vs-clusterIP via isHeadlessServiceLabel - without requiring every pod template to
+// duplicate them.
+func mirrorServiceLabels(ep *model.IstioEndpoint, slice *v1.EndpointSlice, rawSvc *corev1.Service) {
+	if ep.Labels == nil {
+		ep.Labels = make(map[string]string)
+	}
+	// Pod-derived labels take precedence over mirrored labels, consistent with how Kubernetes'
+	// own EndpointSlice mirroring never overrides endpoint-specific data.
+	mirror := func(k, v string) {
+		if v == "" {
+			return
+		}
+		if _, exists := ep.Labels[k]; !exists {
+			ep.Labels[k] = v
+		}
+	}
+	// The well-known slice labels are mirrored before the Service's own labels, so they win
+	// over a Service label with the same key.
+	sliceLabels := slice.GetLabels()
+	mirror(v1beta1.LabelServiceName, sliceLabels[v1beta1.LabelServiceName])
+	mirror(v1beta1.LabelManagedBy, sliceLabels[v1beta1.LabelManagedBy])
+	mirror(isHeadlessServiceLabel, sliceLabels[isHeadlessServiceLabel])
+	if rawSvc != nil {
+		for k, v := range rawSvc.Labels {
+			mirror(k, v)
+		}
+	}
+}
+
 func (esc *endpointSliceController) updateEndpointCacheForSlice(hostName host.Name, slice *v1.EndpointSlice) {
-	var endpoints []*model.IstioEndpoint
-	if slice.AddressType == v1.AddressTypeFQDN {
-		// TODO(https://github.com/istio/istio/issues/34995) support FQDN endpointslice
+	// AddressTypeFQDN slices are used to manually front things like third-party SaaS endpoints
+	// or regional STS aliases: e.Addresses holds a hostname rather than an IP, so there is no
+	// Pod to resolve and no topology hints to honor, but everything else about building an
+	// IstioEndpoint still applies. Gated behind features.EnableFQDNEndpointSlices because
+	// emitting a hostname as IstioEndpoint.Address only makes sense for a Service the
+	// cluster-building layer also resolves as LOGICAL_DNS/STRICT_DNS (ServiceEntry-style); that
+	// resolution-type wiring doesn't exist yet, so this stays opt-in until it does (see
+	// https://github.com/istio/istio/issues/34995).
+	isFQDN := slice.AddressType == v1.AddressTypeFQDN
+	if isFQDN && !features.EnableFQDNEndpointSlices {
 		return
 	}
+	var endpoints []*model.IstioEndpoint
 	svc := esc.c.GetService(hostName)
 	discoverabilityPolicy := esc.c.exports.EndpointDiscoverabilityPolicy(svc)
+	rawSvc := esc.parentServiceFor(slice)
+	publishNotReady := rawSvc != nil && rawSvc.Spec.PublishNotReadyAddresses
+	endpointWeights := endpointWeightsFrom(rawSvc)
 
 	for _, e := range slice.Endpoints {
-		// Draining tracking is only enabled if persistent sessions is enabled.
-		// If we start using them for other features, this can be adjusted.
-		healthStatus := endpointHealthStatus(svc, e)
+		// Draining tracking is enabled for persistent sessions, or for any service when
+		// features.EnableGracefulTermination is set - see endpointHealthStatus.
+		healthStatus := endpointHealthStatus(svc, e, publishNotReady)
 		for _, a := range e.Addresses {
-			pod, expectedPod := getPod(esc.c, a, &metav1.ObjectMeta{Name: slice.Name, Namespace: slice.Namespace}, e.TargetRef, hostName)
-			if pod == nil && expectedPod {
-				continue
+			var pod *corev1.Pod
+			if !isFQDN {
+				var expectedPod bool
+				pod, expectedPod = getPod(esc.c, a, &metav1.ObjectMeta{Name: slice.Name, Namespace: slice.Namespace}, e.TargetRef, hostName)
+				if pod == nil && expectedPod {
+					continue
+				}
 			}
 			builder := NewEndpointBuilder(esc.c, pod)
 			// EDS and ServiceEntry use name for service port - ADS will need to map to numbers.
@@ -271,7 +690,16 @@ func (esc *endpointSliceController) updateEndpointCacheForSlice(hostName host.Na
 					portName = *port.Name
 				}
 
+				// For FQDN slices, a is the hostname itself; buildIstioEndpoint sets it as
+				// IstioEndpoint.Address the same as for an IP.
 				istioEndpoint := builder.buildIstioEndpoint(a, portNum, portName, discoverabilityPolicy, healthStatus)
+				mirrorServiceLabels(istioEndpoint, slice, rawSvc)
+				if weight, ok := endpointWeightFor(pod, endpointWeights); ok {
+					istioEndpoint.LbWeight = weight
+				}
+				if !isFQDN {
+					applyTopologyHint(istioEndpoint, topologyHintFor(e))
+				}
 				endpoints = append(endpoints, istioEndpoint)
 			}
 		}
@@ -279,22 +707,70 @@ func (esc *endpointSliceController) updateEndpointCacheForSlice(hostName host.Na
 	esc.endpointCache.Update(hostName, slice.Name, endpoints)
 }
 
-func (esc *endpointSliceController) buildIstioEndpointsWithService(name, namespace string, hostName host.Name, updateCache bool) []*model.IstioEndpoint {
-	esLabelSelector := endpointSliceSelectorForService(name)
-	slices := esc.slices.List(namespace, esLabelSelector)
-	if len(slices) == 0 {
-		log.Debugf("endpoint slices of (%s, %s) not found", name, namespace)
-		return nil
-	}
+// topologyHint mirrors the subset of EndpointSlice's topology-aware routing hints (forZones,
+// and the newer forNodes) that Istio understands for a single endpoint.
+type topologyHint struct {
+	zones sets.Set[string]
+	nodes sets.Set[string]
+}
 
-	if updateCache {
-		// A cache update was requested. Rebuild the endpoints for these slices.
-		for _, slice := range slices {
-			esc.updateEndpointCacheForSlice(hostName, slice)
+func (h topologyHint) isSet() bool {
+	return len(h.zones) > 0 || len(h.nodes) > 0
+}
+
+func topologyHintFor(e v1.Endpoint) topologyHint {
+	var hint topologyHint
+	if e.Hints == nil {
+		return hint
+	}
+	if len(e.Hints.ForZones) > 0 {
+		hint.zones = sets.New[string]()
+		for _, z := range e.Hints.ForZones {
+			hint.zones.Insert(z.Name)
 		}
 	}
+	if len(e.Hints.ForNodes) > 0 {
+		hint.nodes = sets.New[string]()
+		for _, n := range e.Hints.ForNodes {
+			hint.nodes.Insert(n.Name)
+		}
+	}
+	return hint
+}
 
-	return esc.endpointCache.Get(hostName)
+// topologyHintZonesLabel and topologyHintNodesLabel carry an endpoint's topology-aware routing
+// hint on the cached IstioEndpoint itself, so endpointSliceCache.GetForProxy can filter a
+// hostname's endpoint list down to a requesting proxy's own zone/node without a separate
+// hint-keyed side index.
+const (
+	topologyHintZonesLabel = "topology.istio.io/zones"
+	topologyHintNodesLabel = "topology.istio.io/nodes"
+)
+
+// applyTopologyHint records hint onto ep's labels when features.PreferTopologyAwareRoutingHints
+// is set, for endpointSliceCache.GetForProxy to filter on.
+func applyTopologyHint(ep *model.IstioEndpoint, hint topologyHint) {
+	if !features.PreferTopologyAwareRoutingHints || !hint.isSet() {
+		return
+	}
+	if ep.Labels == nil {
+		ep.Labels = make(map[string]string)
+	}
+	if len(hint.zones) > 0 {
+		ep.Labels[topologyHintZonesLabel] = strings.Join(sortedStrings(hint.zones), ",")
+	}
+	if len(hint.nodes) > 0 {
+		ep.Labels[topologyHintNodesLabel] = strings.Join(sortedStrings(hint.nodes), ",")
+	}
+}
+
+func sortedStrings(s sets.Set[string]) []string {
+	out := make([]string, 0, len(s))
+	for v := range s {
+		out = append(out, v)
+	}
+	sort.Strings(out)
+	return out
 }
 
 func getServiceNamespacedName(slice *v1.EndpointSlice) types.NamespacedName {
@@ -304,11 +780,13 @@ func getServiceNamespacedName(slice *v1.EndpointSlice) types.NamespacedName {
 	}
 }
 
-// endpointKey unique identifies an endpoint by IP and port name
+// endpointKey unique identifies an endpoint by address and port name. address holds an IP for
+// ordinary EndpointSlices, or a hostname for AddressTypeFQDN ones - either way it matches
+// IstioEndpoint.Address, so an IP and a hostname share a key only if their strings are identical.
 // This is used for deduping endpoints across slices.
 type endpointKey struct {
-	ip   string
-	port string
+	address string
+	port    string
 }
 
 type endpointSliceCache struct {
@@ -381,6 +859,74 @@ func (e *endpointSliceCache) get(hostname host.Name) []*model.IstioEndpoint {
 	return endpoints
 }
 
+// GetForProxy returns hostname's endpoints, preferring the subset whose topology-aware routing
+// hint (see applyTopologyHint) names zone or node - the same preference a topology-aware kube-proxy
+// would apply, for the proxy's EDS response to prefer same-zone/same-node endpoints. If
+// features.PreferTopologyAwareRoutingHints is off, no cached endpoint carries a hint, or the
+// hint-matching subset is empty (e.g. the hint names a zone with no ready endpoints left), the
+// full unfiltered list is returned instead - a stale or inapplicable hint must never leave a
+// proxy with zero endpoints.
+//
+// Nothing in this trimmed tree calls GetForProxy yet: the per-proxy EDS/CDS generator it's meant
+// to replace Get for lives in pilot/pkg/xds, which isn't part of this snapshot, so pushEDS below
+// still pushes Get's unfiltered list to every proxy watching a hostname.
+func (e *endpointSliceCache) GetForProxy(hostname host.Name, zone, node string) []*model.IstioEndpoint {
+	all := e.Get(hostname)
+	if !features.PreferTopologyAwareRoutingHints {
+		return all
+	}
+	matched := make([]*model.IstioEndpoint, 0, len(all))
+	for _, ep := range all {
+		if endpointMatchesTopologyHint(ep, zone, node) {
+			matched = append(matched, ep)
+		}
+	}
+	if len(matched) == 0 {
+		return all
+	}
+	return matched
+}
+
+func endpointMatchesTopologyHint(ep *model.IstioEndpoint, zone, node string) bool {
+	if zone != "" {
+		for _, z := range strings.Split(ep.Labels[topologyHintZonesLabel], ",") {
+			if z == zone {
+				return true
+			}
+		}
+	}
+	if node != "" {
+		for _, n := range strings.Split(ep.Labels[topologyHintNodesLabel], ",") {
+			if n == node {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getForSourceCluster returns only the endpoints cached under sourceCluster's own slice keys
+// (see mcsSliceKey), so that an MCS shard update for one remote cluster never carries another
+// remote cluster's endpoints along with it.
+func (e *endpointSliceCache) getForSourceCluster(hostname host.Name, sourceCluster cluster.ID) []*model.IstioEndpoint {
+	prefix := mcsSliceKey(sourceCluster, "")
+	var endpoints []*model.IstioEndpoint
+	found := sets.New[endpointKey]()
+	for sliceKey, eps := range e.endpointsByServiceAndSlice[hostname] {
+		if !strings.HasPrefix(sliceKey, prefix) {
+			continue
+		}
+		for _, ep := range eps {
+			key := endpointKey{ep.Address, ep.ServicePortName}
+			if found.InsertContains(key) {
+				continue
+			}
+			endpoints = append(endpoints, ep)
+		}
+	}
+	return endpoints
+}
+
 func (e *endpointSliceCache) Has(hostname host.Name) bool {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
@@ -0,0 +1,604 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"sort"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/discovery/v1"
+	"k8s.io/api/discovery/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/pilot/pkg/features"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/cluster"
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/util/sets"
+)
+
+func TestEndpointHealthStatus(t *testing.T) {
+	truth, falsity := true, false
+
+	cases := []struct {
+		name                      string
+		svc                       *model.Service
+		conditions                v1.EndpointConditions
+		publishNotReadyAddresses  bool
+		enableGracefulTermination bool
+		want                      model.HealthStatus
+	}{
+		{
+			name:       "ready endpoint is healthy",
+			conditions: v1.EndpointConditions{Ready: &truth},
+			want:       model.Healthy,
+		},
+		{
+			name:                     "publishNotReadyAddresses overrides not-ready",
+			conditions:               v1.EndpointConditions{Ready: &falsity},
+			publishNotReadyAddresses: true,
+			want:                     model.Healthy,
+		},
+		{
+			name:       "not ready, not serving/terminating is unhealthy",
+			conditions: v1.EndpointConditions{Ready: &falsity, Serving: &falsity},
+			want:       model.UnHealthy,
+		},
+		{
+			name:       "serving+terminating without EnableGracefulTermination or persistent sessions is unhealthy",
+			conditions: v1.EndpointConditions{Ready: &falsity, Serving: &truth, Terminating: &truth},
+			want:       model.UnHealthy,
+		},
+		{
+			name:                      "serving+terminating with EnableGracefulTermination is draining for any service",
+			conditions:                v1.EndpointConditions{Ready: &falsity, Serving: &truth, Terminating: &truth},
+			enableGracefulTermination: true,
+			want:                      model.Draining,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			old := features.EnableGracefulTermination
+			features.EnableGracefulTermination = tt.enableGracefulTermination
+			defer func() { features.EnableGracefulTermination = old }()
+
+			got := endpointHealthStatus(tt.svc, v1.Endpoint{Conditions: tt.conditions}, tt.publishNotReadyAddresses)
+			if got != tt.want {
+				t.Errorf("endpointHealthStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEndpointHealthStatusPersistentSession(t *testing.T) {
+	truth, falsity := true, false
+	old := features.PersistentSessionLabel
+	features.PersistentSessionLabel = "istio.io/persistent-session"
+	defer func() { features.PersistentSessionLabel = old }()
+
+	svc := &model.Service{
+		Attributes: model.ServiceAttributes{
+			Labels: map[string]string{features.PersistentSessionLabel: "true"},
+		},
+	}
+	conditions := v1.EndpointConditions{Ready: &falsity, Serving: &truth, Terminating: &truth}
+
+	got := endpointHealthStatus(svc, v1.Endpoint{Conditions: conditions}, false)
+	if got != model.Draining {
+		t.Errorf("endpointHealthStatus() = %v, want %v (persistent session service should drain regardless of EnableGracefulTermination)", got, model.Draining)
+	}
+}
+
+// TestStaleSliceKeys seeds a cache with entries for three slices, simulates one of them being
+// deleted out-of-band (e.g. while pilot-discovery was down and missed the Delete event), and
+// verifies reconcile's pruning helper identifies only the missing one as stale.
+//
+// This stops short of reconcile()/Run() themselves: both also need esc.c.GetService and
+// esc.slices/esc.mcsSlices backed by a real informer, and every other test in this file is
+// deliberately scoped to avoid constructing a *Controller, since nothing else in this package
+// (e.g. a fake-clientset test helper) is available to build one from.
+func TestStaleSliceKeys(t *testing.T) {
+	cached := map[string][]*model.IstioEndpoint{
+		"slice-a":          {{Address: "10.0.0.1"}},
+		"slice-b":          {{Address: "10.0.0.2"}},
+		"remote-1/slice-c": {{Address: "10.0.0.3"}},
+	}
+
+	cases := []struct {
+		name string
+		live sets.Set[string]
+		want []string
+	}{
+		{
+			name: "all slices still live",
+			live: sets.New("slice-a", "slice-b", "remote-1/slice-c"),
+			want: nil,
+		},
+		{
+			name: "slice-b deleted out-of-band",
+			live: sets.New("slice-a", "remote-1/slice-c"),
+			want: []string{"slice-b"},
+		},
+		{
+			name: "remote cluster's slice deleted out-of-band",
+			live: sets.New("slice-a", "slice-b"),
+			want: []string{"remote-1/slice-c"},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			got := staleSliceKeys(cached, tt.live)
+			sort.Strings(got)
+			if len(got) != len(tt.want) {
+				t.Fatalf("staleSliceKeys() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("staleSliceKeys() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// TestMCSSliceKeySourceCluster verifies reconcile can recover which remote cluster a pruned MCS
+// slice key belongs to, so it can push that cluster's withdrawal under its own shard instead of
+// bleeding into another cluster's (see pushMCSEDS).
+func TestMCSSliceKeySourceCluster(t *testing.T) {
+	if got, want := mcsSliceKeySourceCluster(mcsSliceKey("remote-1", "some-slice")), cluster.ID("remote-1"); got != want {
+		t.Errorf("mcsSliceKeySourceCluster() = %v, want %v", got, want)
+	}
+}
+
+// TestMCSClusterSetHostnameParts verifies the clusterset.local hostname parsing reconcile relies
+// on to recognize MCS-imported hostnames is the exact inverse of mcsClusterSetHostname.
+func TestMCSClusterSetHostnameParts(t *testing.T) {
+	name, ns, ok := mcsClusterSetHostnameParts(mcsClusterSetHostname("my-svc", "my-ns"))
+	if !ok || name != "my-svc" || ns != "my-ns" {
+		t.Errorf("mcsClusterSetHostnameParts() = (%q, %q, %v), want (\"my-svc\", \"my-ns\", true)", name, ns, ok)
+	}
+
+	if _, _, ok := mcsClusterSetHostnameParts("my-svc.my-ns.svc.cluster.local"); ok {
+		t.Errorf("mcsClusterSetHostnameParts() matched a non-MCS hostname")
+	}
+}
+
+// TestEndpointSliceCacheGetForSourceCluster verifies getForSourceCluster returns only the
+// endpoints cached under the requested cluster's own slice keys, so a shard update for one
+// remote cluster never carries another remote cluster's endpoints along with it (see
+// pushMCSEDS).
+func TestEndpointSliceCacheGetForSourceCluster(t *testing.T) {
+	hostname := host.Name("my-svc.clusterset.local")
+	cache := newEndpointSliceCache()
+	cache.Update(hostname, mcsSliceKey("remote-1", "slice-a"), []*model.IstioEndpoint{
+		{Address: "10.0.0.1", ServicePortName: "http"},
+	})
+	cache.Update(hostname, mcsSliceKey("remote-2", "slice-b"), []*model.IstioEndpoint{
+		{Address: "10.0.0.2", ServicePortName: "http"},
+	})
+
+	got := cache.getForSourceCluster(hostname, "remote-1")
+	if len(got) != 1 || got[0].Address != "10.0.0.1" {
+		t.Errorf("getForSourceCluster() = %v, want only 10.0.0.1", got)
+	}
+
+	got = cache.getForSourceCluster(hostname, "remote-2")
+	if len(got) != 1 || got[0].Address != "10.0.0.2" {
+		t.Errorf("getForSourceCluster() = %v, want only 10.0.0.2", got)
+	}
+}
+
+// TestUpdateEndpointCacheForSliceSkipsFQDN verifies updateEndpointCacheForSlice takes the early
+// return for an AddressType FQDN slice while features.EnableFQDNEndpointSlices is off (the
+// default), without touching esc.c, which this test leaves nil. The flag-enabled path builds
+// IstioEndpoints through esc.c.GetService/exports/parentServiceFor, which this trimmed tree has
+// no fake *Controller to construct, so it isn't covered here.
+func TestUpdateEndpointCacheForSliceSkipsFQDN(t *testing.T) {
+	esc := &endpointSliceController{endpointCache: newEndpointSliceCache()}
+	hostname := host.Name("backend.my-ns.svc.cluster.local")
+	slice := &v1.EndpointSlice{
+		ObjectMeta:  metav1.ObjectMeta{Name: "backend-fqdn", Namespace: "my-ns"},
+		AddressType: v1.AddressTypeFQDN,
+		Endpoints: []v1.Endpoint{
+			{Addresses: []string{"backend.example.com"}},
+		},
+		Ports: []v1.EndpointPort{{Name: strPtr("http")}},
+	}
+
+	esc.updateEndpointCacheForSlice(hostname, slice)
+
+	if esc.endpointCache.Has(hostname) {
+		t.Errorf("updateEndpointCacheForSlice() populated the cache for an AddressType FQDN slice, want no-op")
+	}
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestEndpointWeightsFrom(t *testing.T) {
+	cases := []struct {
+		name string
+		svc  *corev1.Service
+		want map[string]uint32
+	}{
+		{
+			name: "nil service",
+			svc:  nil,
+			want: nil,
+		},
+		{
+			name: "no annotation",
+			svc:  &corev1.Service{},
+			want: nil,
+		},
+		{
+			name: "valid entries",
+			svc: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "my-ns",
+					Name:        "backend",
+					Annotations: map[string]string{endpointWeightsAnnotation: "pod-a=10, pod-b=1"},
+				},
+			},
+			want: map[string]uint32{"pod-a": 10, "pod-b": 1},
+		},
+		{
+			name: "malformed entries are skipped, valid ones still parsed",
+			svc: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "my-ns",
+					Name:      "backend",
+					Annotations: map[string]string{
+						endpointWeightsAnnotation: "pod-a=10,no-equals-sign,pod-b=not-a-number,=5,pod-c=",
+					},
+				},
+			},
+			want: map[string]uint32{"pod-a": 10},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			got := endpointWeightsFrom(tt.svc)
+			if len(got) != len(tt.want) {
+				t.Fatalf("endpointWeightsFrom() = %v, want %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("endpointWeightsFrom()[%q] = %d, want %d", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+// TestEndpointWeightFor verifies the Service-level annotation takes precedence over the pod's
+// own endpointWeightLabel, and that an explicit weight of 0 is reported as found rather than
+// being treated the same as "no weight configured".
+func TestEndpointWeightFor(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "pod-a",
+			Labels: map[string]string{endpointWeightLabel: "5"},
+		},
+	}
+
+	cases := []struct {
+		name       string
+		pod        *corev1.Pod
+		weights    map[string]uint32
+		wantWeight uint32
+		wantFound  bool
+	}{
+		{
+			name:      "nil pod",
+			pod:       nil,
+			weights:   map[string]uint32{"pod-a": 10},
+			wantFound: false,
+		},
+		{
+			name:       "service annotation takes precedence over pod label",
+			pod:        pod,
+			weights:    map[string]uint32{"pod-a": 10},
+			wantWeight: 10,
+			wantFound:  true,
+		},
+		{
+			name:       "falls back to pod label when annotation doesn't mention the pod",
+			pod:        pod,
+			weights:    map[string]uint32{"other-pod": 10},
+			wantWeight: 5,
+			wantFound:  true,
+		},
+		{
+			name:       "explicit zero from the annotation is honored, not dropped",
+			pod:        pod,
+			weights:    map[string]uint32{"pod-a": 0},
+			wantWeight: 0,
+			wantFound:  true,
+		},
+		{
+			name: "explicit zero from the pod label is honored, not dropped",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "pod-b",
+					Labels: map[string]string{endpointWeightLabel: "0"},
+				},
+			},
+			weights:    nil,
+			wantWeight: 0,
+			wantFound:  true,
+		},
+		{
+			name:      "no annotation entry and no pod label",
+			pod:       &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-c"}},
+			weights:   nil,
+			wantFound: false,
+		},
+		{
+			name: "malformed pod label is ignored",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "pod-d",
+					Labels: map[string]string{endpointWeightLabel: "not-a-number"},
+				},
+			},
+			weights:   nil,
+			wantFound: false,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			gotWeight, gotFound := endpointWeightFor(tt.pod, tt.weights)
+			if gotFound != tt.wantFound || gotWeight != tt.wantWeight {
+				t.Errorf("endpointWeightFor() = (%d, %v), want (%d, %v)", gotWeight, gotFound, tt.wantWeight, tt.wantFound)
+			}
+		})
+	}
+}
+
+// TestMirrorServiceLabels verifies the precedence mirrorServiceLabels documents: labels already
+// on the endpoint (pod-derived) are never overridden, the well-known slice-only labels
+// (IsHeadlessService/LabelServiceName/LabelManagedBy) are mirrored next, and a Service's own
+// labels fill in last, only if still unset. Empty-valued mirror candidates are skipped entirely.
+func TestMirrorServiceLabels(t *testing.T) {
+	slice := &v1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				v1beta1.LabelServiceName: "backend",
+				v1beta1.LabelManagedBy:   "endpointslice-controller.k8s.io",
+				isHeadlessServiceLabel:   "true",
+			},
+		},
+	}
+	rawSvc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"app":                     "backend",
+				v1beta1.LabelServiceName: "service-label-should-lose-to-slice-label",
+			},
+		},
+	}
+
+	ep := &model.IstioEndpoint{
+		Labels: map[string]string{"app": "pod-derived-wins"},
+	}
+	mirrorServiceLabels(ep, slice, rawSvc)
+
+	want := map[string]string{
+		"app":                     "pod-derived-wins",
+		v1beta1.LabelServiceName: "backend",
+		v1beta1.LabelManagedBy:   "endpointslice-controller.k8s.io",
+		isHeadlessServiceLabel:   "true",
+	}
+	if len(ep.Labels) != len(want) {
+		t.Fatalf("mirrorServiceLabels() labels = %v, want %v", ep.Labels, want)
+	}
+	for k, v := range want {
+		if ep.Labels[k] != v {
+			t.Errorf("mirrorServiceLabels() labels[%q] = %q, want %q", k, ep.Labels[k], v)
+		}
+	}
+}
+
+// TestMirrorServiceLabelsSkipsEmptyValues verifies a well-known slice label that is absent (and
+// so reads as "") is not mirrored onto the endpoint as an empty-valued label.
+func TestMirrorServiceLabelsSkipsEmptyValues(t *testing.T) {
+	slice := &v1.EndpointSlice{}
+	ep := &model.IstioEndpoint{}
+	mirrorServiceLabels(ep, slice, nil)
+
+	if _, exists := ep.Labels[isHeadlessServiceLabel]; exists {
+		t.Errorf("mirrorServiceLabels() set %q from an absent slice label, want unset", isHeadlessServiceLabel)
+	}
+}
+
+// TestApplyTopologyHint verifies the real, shipped topology-hint behavior: applyTopologyHint
+// only labels an endpoint when features.PreferTopologyAwareRoutingHints is set and the endpoint
+// actually carries a hint, and it never drops zones/nodes the hint did carry.
+func TestApplyTopologyHint(t *testing.T) {
+	zoneNodeHint := topologyHint{
+		zones: sets.New("zone-b", "zone-a"),
+		nodes: sets.New("node-1"),
+	}
+
+	cases := []struct {
+		name       string
+		enabled    bool
+		hint       topologyHint
+		wantLabels map[string]string
+	}{
+		{
+			name:       "disabled leaves endpoint unlabeled even with a hint",
+			enabled:    false,
+			hint:       zoneNodeHint,
+			wantLabels: nil,
+		},
+		{
+			name:       "enabled with no hint leaves endpoint unlabeled",
+			enabled:    true,
+			hint:       topologyHint{},
+			wantLabels: nil,
+		},
+		{
+			name:    "enabled with a hint sorts zones and sets both labels",
+			enabled: true,
+			hint:    zoneNodeHint,
+			wantLabels: map[string]string{
+				topologyHintZonesLabel: "zone-a,zone-b",
+				topologyHintNodesLabel: "node-1",
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			old := features.PreferTopologyAwareRoutingHints
+			features.PreferTopologyAwareRoutingHints = tt.enabled
+			defer func() { features.PreferTopologyAwareRoutingHints = old }()
+
+			ep := &model.IstioEndpoint{Address: "10.0.0.1"}
+			applyTopologyHint(ep, tt.hint)
+			if len(ep.Labels) != len(tt.wantLabels) {
+				t.Fatalf("applyTopologyHint() labels = %v, want %v", ep.Labels, tt.wantLabels)
+			}
+			for k, v := range tt.wantLabels {
+				if ep.Labels[k] != v {
+					t.Errorf("applyTopologyHint() labels[%q] = %q, want %q", k, ep.Labels[k], v)
+				}
+			}
+		})
+	}
+}
+
+// TestTopologyHintFor verifies topologyHintFor collects forZones/forNodes independently, and
+// reports isSet()=false when an Endpoint carries neither.
+func TestTopologyHintFor(t *testing.T) {
+	if got := topologyHintFor(v1.Endpoint{}); got.isSet() {
+		t.Errorf("topologyHintFor() of an Endpoint with no Hints = %+v, want isSet() == false", got)
+	}
+
+	hint := topologyHintFor(v1.Endpoint{
+		Hints: &v1.EndpointHints{
+			ForZones: []v1.ForZone{{Name: "zone-a"}},
+		},
+	})
+	if !hint.isSet() || !hint.zones.Contains("zone-a") || len(hint.nodes) != 0 {
+		t.Errorf("topologyHintFor() = %+v, want only zone-a set", hint)
+	}
+}
+
+// TestEndpointSliceCacheGetMixedAddressTypes verifies IPv4, IPv6, and FQDN endpoints for the
+// same Service, each with a distinct address string, are all kept by Get - the
+// endpointKey{address, port} key dedups only identical address strings on the same port.
+func TestEndpointSliceCacheGetMixedAddressTypes(t *testing.T) {
+	hostname := host.Name("my-svc.my-ns.svc.cluster.local")
+	cache := newEndpointSliceCache()
+
+	cache.Update(hostname, "ipv4-slice", []*model.IstioEndpoint{
+		{Address: "10.0.0.1", ServicePortName: "http"},
+	})
+	cache.Update(hostname, "ipv6-slice", []*model.IstioEndpoint{
+		{Address: "2001:db8::1", ServicePortName: "http"},
+	})
+	cache.Update(hostname, "fqdn-slice", []*model.IstioEndpoint{
+		{Address: "backend.example.com", ServicePortName: "http"},
+	})
+
+	got := cache.Get(hostname)
+	var gotAddrs []string
+	for _, ep := range got {
+		gotAddrs = append(gotAddrs, ep.Address)
+	}
+	sort.Strings(gotAddrs)
+	want := []string{"10.0.0.1", "2001:db8::1", "backend.example.com"}
+	sort.Strings(want)
+	if len(gotAddrs) != len(want) {
+		t.Fatalf("Get() addresses = %v, want %v", gotAddrs, want)
+	}
+	for i := range gotAddrs {
+		if gotAddrs[i] != want[i] {
+			t.Errorf("Get() addresses = %v, want %v", gotAddrs, want)
+		}
+	}
+
+	// Re-seeding the same FQDN slice with the same address must still dedup to one entry.
+	cache.Update(hostname, "fqdn-slice", []*model.IstioEndpoint{
+		{Address: "backend.example.com", ServicePortName: "http"},
+	})
+	if got := cache.Get(hostname); len(got) != 3 {
+		t.Errorf("Get() returned %d endpoints after re-update, want 3", len(got))
+	}
+}
+
+// TestEndpointSliceCacheGetForProxy verifies GetForProxy filters to the zone/node a hint names,
+// but falls back to the unfiltered set whenever that would leave the proxy with nothing: the
+// feature disabled, no endpoint carrying a hint, or a hint naming a zone/node with no endpoints.
+func TestEndpointSliceCacheGetForProxy(t *testing.T) {
+	hostname := host.Name("my-svc.my-ns.svc.cluster.local")
+
+	old := features.PreferTopologyAwareRoutingHints
+	defer func() { features.PreferTopologyAwareRoutingHints = old }()
+
+	newCache := func() *endpointSliceCache {
+		cache := newEndpointSliceCache()
+		cache.Update(hostname, "slice-1", []*model.IstioEndpoint{
+			{Address: "10.0.0.1", ServicePortName: "http", Labels: map[string]string{topologyHintZonesLabel: "zone-a"}},
+			{Address: "10.0.0.2", ServicePortName: "http", Labels: map[string]string{topologyHintZonesLabel: "zone-b"}},
+			{Address: "10.0.0.3", ServicePortName: "http"},
+		})
+		return cache
+	}
+
+	t.Run("disabled returns every endpoint regardless of hints", func(t *testing.T) {
+		features.PreferTopologyAwareRoutingHints = false
+		got := newCache().GetForProxy(hostname, "zone-a", "")
+		if len(got) != 3 {
+			t.Errorf("GetForProxy() = %d endpoints, want 3 (unfiltered)", len(got))
+		}
+	})
+
+	t.Run("enabled filters to the endpoints matching the requested zone", func(t *testing.T) {
+		features.PreferTopologyAwareRoutingHints = true
+		got := newCache().GetForProxy(hostname, "zone-a", "")
+		if len(got) != 1 || got[0].Address != "10.0.0.1" {
+			t.Errorf("GetForProxy() = %v, want only 10.0.0.1", got)
+		}
+	})
+
+	t.Run("hint naming a zone with no endpoints falls back to the unfiltered set", func(t *testing.T) {
+		features.PreferTopologyAwareRoutingHints = true
+		got := newCache().GetForProxy(hostname, "zone-c", "")
+		if len(got) != 3 {
+			t.Errorf("GetForProxy() = %d endpoints, want 3 (fallback to unfiltered)", len(got))
+		}
+	})
+
+	t.Run("no endpoint carries a hint falls back to the unfiltered set", func(t *testing.T) {
+		features.PreferTopologyAwareRoutingHints = true
+		cache := newEndpointSliceCache()
+		cache.Update(hostname, "slice-1", []*model.IstioEndpoint{
+			{Address: "10.0.0.1", ServicePortName: "http"},
+			{Address: "10.0.0.2", ServicePortName: "http"},
+		})
+		got := cache.GetForProxy(hostname, "zone-a", "")
+		if len(got) != 2 {
+			t.Errorf("GetForProxy() = %d endpoints, want 2 (fallback to unfiltered)", len(got))
+		}
+	})
+}